@@ -9,7 +9,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"reflect"
 	"strconv"
 	"strings"
@@ -64,17 +63,52 @@ type Change struct {
 	Type   ChangeType  `json:"type,omitempty"`
 }
 
-// Compute computes the differences between to objects x and y.
+// SliceChanges is the representation compareSlices and compareSlicesLCS use
+// for the changes found within a single slice or array field: a map from a
+// key describing the affected element (see compareSlices and
+// Engine.compareSlicesLCS) to the Change found there. It is a distinct type
+// from a plain map[string]Change, the representation compareMaps uses for
+// map fields, so that Diff.Operations can tell which key format it is
+// looking at when it rebuilds a JSON Pointer.
+type SliceChanges map[string]Change
+
+// Compute computes the differences between to objects x and y, configured
+// by the given options (see Option).
 //
 // x and y must be both structures and have to share the same type.
-func Compute(x, y interface{}) (Diff, error) {
-	return Engine{}.Compute(x, y)
+func Compute(x, y interface{}, opts ...Option) (Diff, error) {
+	return Engine{}.Compute(x, y, opts...)
 }
 
 // An Engine provides a flexible diff calculator.
 type Engine struct {
 	ExcludeFieldList []string
 	MaxDepth         int // XXX(gilliek): not yet implemented
+
+	// SliceDiffMode selects the algorithm used to compare slices and
+	// arrays. It defaults to SliceDiffIndex.
+	SliceDiffMode SliceDiffMode
+
+	// SliceRebindThreshold is the minimum similarity ratio, in [0, 1],
+	// at or above which a deletion immediately followed by an insertion is
+	// reported as a single modification instead of a DEL and an ADD.
+	// It only applies when SliceDiffMode is SliceDiffLCS. Zero means
+	// SliceRebindThreshold (the package default).
+	SliceRebindThreshold float64
+
+	// The fields below are populated by Option values passed to Compute;
+	// they have no exported equivalent and are not meant to be set
+	// directly.
+	ignoreFields          []ignoreFieldsRule
+	ignoreTypes           []reflect.Type
+	ignoreUnexportedTypes []reflect.Type
+	filters               []pathFilter
+	comparers             []comparerRule
+	transformers          []transformerRule
+	floatEquate           floatEquate
+	equateNaNs            bool
+	equateInfs            bool
+	reporters             []Reporter
 }
 
 // IsIgnored reports whether a field is ignored by the Engine configuration.
@@ -88,10 +122,14 @@ func (e Engine) IsIgnored(field string) bool {
 }
 
 // Compute computes the differences between to objects x and y using the
-// parameters defined in the Engine.
+// parameters defined in the Engine and the given options (see Option).
 //
 // x and y must be both structures and have share the same type.
-func (e Engine) Compute(x, y interface{}) (Diff, error) {
+func (e Engine) Compute(x, y interface{}, opts ...Option) (Diff, error) {
+	for _, opt := range opts {
+		opt.apply(&e)
+	}
+
 	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
 	tx, ty := vx.Type(), vy.Type()
 
@@ -104,40 +142,57 @@ func (e Engine) Compute(x, y interface{}) (Diff, error) {
 		return nil, errors.New("input values are not struct")
 	}
 
-	xNumFields := vx.NumField()
+	// The Diff returned below is assembled by builder, a Reporter like any
+	// other registered through Reporters: compareStructs's own return
+	// value still drives the comparison (and is what nested fields see as
+	// their raw result), but Compute reads the final Diff back from the
+	// Reporter's observations instead of type-asserting it directly.
+	builder := &diffBuilder{}
+	e.reporters = append(e.reporters, builder)
 
-	delta := make(Diff)
+	e.compareStructs(vx, vy, nil)
 
-	for i := 0; i < xNumFields; i++ {
-		fx := vx.Field(i)
-		typ := tx.Field(i)
+	delta := builder.delta
+	if delta == nil {
+		delta = make(Diff)
+	}
+	return delta, nil
+}
 
-		// skip non-exported fields and the ones that are excluded
-		if !isExported(typ.Name) || e.IsIgnored(typ.Name) {
-			continue
-		}
+func (e Engine) compareValues(fx, fy reflect.Value, path Path) interface{} {
+	typ := fx.Type()
 
-		fy := vy.FieldByName(typ.Name)
+	if e.isTypeIgnored(typ) {
+		return nil
+	}
 
-		if d := e.compareValues(fx, fy); d != nil {
-			delta[typ.Name] = d
-		}
+	// A transformer is applied at most once per value: its result feeds
+	// directly into the kind switch below rather than looping back
+	// through this function, so that a transformer whose output type
+	// matches its input type (e.g. string to string) cannot recurse
+	// forever.
+	if t, ok := e.findTransformer(typ); ok {
+		fx = t.fn.Call([]reflect.Value{fx})[0]
+		fy = t.fn.Call([]reflect.Value{fy})[0]
+		typ = fx.Type()
 	}
 
-	return delta, nil
-}
+	if fn, ok := e.findComparer(typ); ok {
+		if fn.Call([]reflect.Value{fx, fy})[0].Bool() {
+			return nil
+		}
+		return Change{OldVal: fx.Interface(), NewVal: fy.Interface(), Type: ModType}
+	}
 
-func (e Engine) compareValues(fx, fy reflect.Value) interface{} {
 	switch fx.Kind() {
 
 	// Structures, slices/arrays and maps must be recursively visited.
 	case reflect.Struct:
-		return e.compareStructs(fx, fy)
+		return e.compareStructs(fx, fy, path)
 	case reflect.Array, reflect.Slice:
-		return e.compareSlices(fx, fy)
+		return e.compareSlices(fx, fy, path)
 	case reflect.Map:
-		// TODO(gilliek): add support for map
-		return nil
+		return e.compareMaps(fx, fy, path)
 
 	// For pointers, only the values pointed are compared.
 	case reflect.Ptr:
@@ -149,7 +204,23 @@ func (e Engine) compareValues(fx, fy reflect.Value) interface{} {
 		} else if fy.IsNil() {
 			return Change{OldVal: fx.Elem().Interface(), NewVal: nil, Type: ModType}
 		}
-		return e.compareValues(fx.Elem(), fy.Elem())
+		return e.compareValues(fx.Elem(), fy.Elem(), path)
+
+	// Interface values are unwrapped to their dynamic value; a change of
+	// dynamic type is reported as a single modification rather than
+	// recursed into.
+	case reflect.Interface:
+		if fx.IsNil() || fy.IsNil() {
+			if fx.IsNil() && fy.IsNil() {
+				return nil
+			}
+			return Change{OldVal: fx.Interface(), NewVal: fy.Interface(), Type: ModType}
+		}
+		ex, ey := fx.Elem(), fy.Elem()
+		if ex.Type() != ey.Type() {
+			return Change{OldVal: ex.Interface(), NewVal: ey.Interface(), Type: ModType}
+		}
+		return e.compareValues(ex, ey, path)
 
 	// "basic" types are directly compared.
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -164,7 +235,7 @@ func (e Engine) compareValues(fx, fy reflect.Value) interface{} {
 		}
 	case reflect.Float32, reflect.Float64:
 		flx, fly := fx.Float(), fy.Float()
-		if math.Abs(flx-fly) > Tolerance {
+		if !e.floatsEqual(flx, fly) {
 			return Change{OldVal: flx, NewVal: fly, Type: ModType}
 		}
 	case reflect.String:
@@ -173,15 +244,36 @@ func (e Engine) compareValues(fx, fy reflect.Value) interface{} {
 			return Change{OldVal: sx, NewVal: sy, Type: ModType}
 		}
 	case reflect.Complex64, reflect.Complex128:
-		// TODO(gilliek): add support for complex numbers
-		return nil
+		cx, cy := fx.Complex(), fy.Complex()
+		if !e.floatsEqual(real(cx), real(cy)) || !e.floatsEqual(imag(cx), imag(cy)) {
+			return Change{OldVal: cx, NewVal: cy, Type: ModType}
+		}
 	}
 
 	return nil
 }
 
-func (e Engine) compareStructs(fx, fy reflect.Value) interface{} {
-	if isFullyNonExportedStruct(fx) {
+// reportChange notifies the configured reporters of a Change computed
+// outside of compareValues (e.g. a whole-element ADD/DEL produced when
+// comparing slices of different lengths).
+func (e Engine) reportChange(path Path, c Change) {
+	if len(e.reporters) == 0 {
+		return
+	}
+	e.notifyPush(path.Last())
+	e.notifyResult(c)
+	e.notifyPop()
+}
+
+func (e Engine) floatComparer() floatEquate {
+	if e.floatEquate != nil {
+		return e.floatEquate
+	}
+	return defaultFloatEquate{}
+}
+
+func (e Engine) compareStructs(fx, fy reflect.Value, path Path) interface{} {
+	if isFullyNonExportedStruct(fx) && !e.isUnexportedIgnored(fx.Type()) {
 		if !isEqual(fx, fy) {
 			return Change{OldVal: fx.Interface(), NewVal: fy.Interface(), Type: ModType}
 		}
@@ -189,20 +281,29 @@ func (e Engine) compareStructs(fx, fy reflect.Value) interface{} {
 	}
 
 	delta := make(Diff)
+	typ := fx.Type()
 	numFields := fx.NumField()
 	for i := 0; i < numFields; i++ {
 		newFx := fx.Field(i)
-		typ := fx.Type().Field(i)
+		field := typ.Field(i)
 
 		// skip non-exported fields and the ones that are excluded
-		if !isExported(typ.Name) || e.IsIgnored(typ.Name) {
+		if !isExported(field.Name) || e.isFieldIgnored(typ, field.Name) {
 			continue
 		}
 
-		newFy := fy.FieldByName(typ.Name)
+		newFy := fy.FieldByName(field.Name)
+		childPath := path.child(PathStep{Field: field.Name})
+		scoped := e.scopedAt(childPath)
 
-		if d := e.compareValues(newFx, newFy); d != nil {
-			delta[typ.Name] = d
+		scoped.notifyPush(childPath.Last())
+		d := scoped.compareValues(newFx, newFy, childPath)
+		scoped.notifyResult(d)
+		scoped.notifyField(fieldKey(field), d)
+		scoped.notifyPop()
+
+		if d != nil {
+			delta[fieldKey(field)] = d
 		}
 	}
 	if len(delta) > 0 {
@@ -211,37 +312,55 @@ func (e Engine) compareStructs(fx, fy reflect.Value) interface{} {
 	return nil
 }
 
-func (e Engine) compareSlices(fx, fy reflect.Value) interface{} {
+func (e Engine) compareSlices(fx, fy reflect.Value, path Path) interface{} {
+	if e.SliceDiffMode == SliceDiffLCS {
+		return e.compareSlicesLCS(fx, fy, path)
+	}
+
 	xLen, yLen := fx.Len(), fy.Len()
-	changes := make(map[string]Change)
+	changes := make(SliceChanges)
 	if xLen == 0 {
 		if yLen == 0 {
 			return nil
 		}
 		for i := 0; i < yLen; i++ {
-			changes[strconv.Itoa(i)] = Change{NewVal: fy.Index(i).Interface(), Type: AddType}
+			c := Change{NewVal: fy.Index(i).Interface(), Type: AddType}
+			changes[strconv.Itoa(i)] = c
+			e.reportChange(path.child(PathStep{Index: i}), c)
 		}
 	} else if yLen == 0 {
 		for i := 0; i < xLen; i++ {
-			changes[strconv.Itoa(i)] = Change{OldVal: fx.Index(i).Interface(), Type: DelType}
+			c := Change{OldVal: fx.Index(i).Interface(), Type: DelType}
+			changes[strconv.Itoa(i)] = c
+			e.reportChange(path.child(PathStep{Index: i}), c)
 		}
 	} else {
 		var maxLen int
 		if xLen > yLen {
 			maxLen = yLen
 			for i := yLen; i < xLen; i++ {
-				changes[strconv.Itoa(i)] = Change{OldVal: fx.Index(i).Interface(), Type: DelType}
+				c := Change{OldVal: fx.Index(i).Interface(), Type: DelType}
+				changes[strconv.Itoa(i)] = c
+				e.reportChange(path.child(PathStep{Index: i}), c)
 			}
 		} else if xLen < yLen {
 			maxLen = xLen
 			for i := xLen; i < yLen; i++ {
-				changes[strconv.Itoa(i)] = Change{NewVal: fy.Index(i).Interface(), Type: AddType}
+				c := Change{NewVal: fy.Index(i).Interface(), Type: AddType}
+				changes[strconv.Itoa(i)] = c
+				e.reportChange(path.child(PathStep{Index: i}), c)
 			}
 		} else {
 			maxLen = xLen
 		}
 		for i := 0; i < maxLen; i++ {
-			if d := e.compareValues(fx.Index(i), fy.Index(i)); d != nil {
+			childPath := path.child(PathStep{Index: i})
+			scoped := e.scopedAt(childPath)
+			scoped.notifyPush(childPath.Last())
+			d := scoped.compareValues(fx.Index(i), fy.Index(i), childPath)
+			scoped.notifyResult(d)
+			scoped.notifyPop()
+			if d != nil {
 				changes[strconv.Itoa(i)] = Change{Val: d, Type: ModType}
 			}
 		}
@@ -252,6 +371,59 @@ func (e Engine) compareSlices(fx, fy reflect.Value) interface{} {
 	return nil
 }
 
+// compareMaps compares fx and fy key by key: a key missing on one side is
+// reported as a DEL or an ADD, a key present on both sides is recursed
+// into. Changes are keyed by the map key serialized with fmt.Sprint, so
+// that non-string keys (ints, structs, ...) still produce a usable Diff.
+func (e Engine) compareMaps(fx, fy reflect.Value, path Path) interface{} {
+	changes := make(map[string]Change)
+	seen := make(map[interface{}]bool, fx.Len())
+
+	for _, k := range fx.MapKeys() {
+		seen[k.Interface()] = true
+		vx, vy := fx.MapIndex(k), fy.MapIndex(k)
+		if !vy.IsValid() {
+			c := Change{OldVal: vx.Interface(), Type: DelType}
+			changes[mapKeyString(k)] = c
+			e.reportChange(path.child(PathStep{Key: k.Interface()}), c)
+			continue
+		}
+
+		childPath := path.child(PathStep{Key: k.Interface()})
+		scoped := e.scopedAt(childPath)
+		scoped.notifyPush(childPath.Last())
+		d := scoped.compareValues(vx, vy, childPath)
+		scoped.notifyResult(d)
+		scoped.notifyPop()
+		if d != nil {
+			changes[mapKeyString(k)] = Change{Val: d, Type: ModType}
+		}
+	}
+
+	for _, k := range fy.MapKeys() {
+		if seen[k.Interface()] {
+			continue
+		}
+		c := Change{NewVal: fy.MapIndex(k).Interface(), Type: AddType}
+		changes[mapKeyString(k)] = c
+		e.reportChange(path.child(PathStep{Key: k.Interface()}), c)
+	}
+
+	if len(changes) > 0 {
+		return changes
+	}
+	return nil
+}
+
+// mapKeyString renders a map key as the string used to key a Diff's
+// changes, using the key itself when it already is a string.
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return fmt.Sprint(k.Interface())
+}
+
 // isExported reports whether a field name is exported based on its name.
 func isExported(fieldName string) bool {
 	if fieldName == "" {
@@ -0,0 +1,37 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// diffBuilder is the Reporter Engine.Compute registers on every call to
+// assemble the Diff it returns. It only tracks the fields of the root
+// struct (depth 1): a struct/slice/map field's own nested structure is
+// already fully built by the recursive comparison before it reaches
+// observeField, so diffBuilder does not need to reconstruct it itself.
+type diffBuilder struct {
+	depth int
+	delta Diff
+}
+
+// PushStep implements Reporter.
+func (b *diffBuilder) PushStep(PathStep) { b.depth++ }
+
+// PopStep implements Reporter.
+func (b *diffBuilder) PopStep() { b.depth-- }
+
+// Report implements Reporter. diffBuilder only needs the raw, unfiltered
+// result delivered to observeField, so leaf-level Result notifications are
+// of no use to it.
+func (b *diffBuilder) Report(Result) {}
+
+// observeField implements containerReporter.
+func (b *diffBuilder) observeField(key string, raw interface{}) {
+	if b.depth != 1 || raw == nil {
+		return
+	}
+	if b.delta == nil {
+		b.delta = make(Diff)
+	}
+	b.delta[key] = raw
+}
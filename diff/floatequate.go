@@ -0,0 +1,80 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "math"
+
+// floatEquate decides whether two float64 values are equal. float32 values
+// are widened to float64 before reaching it, as reflect.Value.Float()
+// already does.
+type floatEquate interface {
+	equal(x, y float64) bool
+}
+
+// defaultFloatEquate reproduces the package's historical behavior: an
+// absolute tolerance of Tolerance.
+type defaultFloatEquate struct{}
+
+func (defaultFloatEquate) equal(x, y float64) bool {
+	return math.Abs(x-y) <= Tolerance
+}
+
+// approxFloatEquate implements EquateApprox.
+type approxFloatEquate struct {
+	fraction, margin float64
+}
+
+func (a approxFloatEquate) equal(x, y float64) bool {
+	limit := a.margin
+	if rel := a.fraction * math.Max(math.Abs(x), math.Abs(y)); rel > limit {
+		limit = rel
+	}
+	return math.Abs(x-y) <= limit
+}
+
+// ulpsFloatEquate implements EquateFloatBits: x and y are equal if their
+// IEEE 754 bit patterns are within ulps units in the last place of one
+// another.
+type ulpsFloatEquate struct {
+	ulps uint32
+}
+
+func (u ulpsFloatEquate) equal(x, y float64) bool {
+	if x == y {
+		return true
+	}
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return false
+	}
+	if math.Signbit(x) != math.Signbit(y) {
+		return false
+	}
+	bx, by := math.Float64bits(x), math.Float64bits(y)
+	diff := bx - by
+	if by > bx {
+		diff = by - bx
+	}
+	return diff <= uint64(u.ulps)
+}
+
+// floatsEqual is the entry point used by compareValues: it applies the
+// NaN/Inf special cases enabled by EquateNaNs and EquateInfs, which a plain
+// floatEquate cannot express (NaN and Inf both fail any finite-distance
+// comparison), before falling back to the Engine's configured floatEquate.
+func (e Engine) floatsEqual(x, y float64) bool {
+	if e.equateNaNs {
+		nx, ny := math.IsNaN(x), math.IsNaN(y)
+		if nx || ny {
+			return nx && ny
+		}
+	}
+	if e.equateInfs {
+		ix, iy := math.IsInf(x, 0), math.IsInf(y, 0)
+		if ix || iy {
+			return ix && iy && math.Signbit(x) == math.Signbit(y)
+		}
+	}
+	return e.floatComparer().equal(x, y)
+}
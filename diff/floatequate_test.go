@@ -0,0 +1,99 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeEquateNaNs(t *testing.T) {
+	type Foo struct {
+		F float64
+	}
+	f1 := Foo{F: math.NaN()}
+	f2 := Foo{F: math.NaN()}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Fatalf("Compute(%v, %v): found no change, expected a MOD since NaN != NaN by default", f1, f2)
+	}
+
+	delta, err = Compute(f1, f2, EquateNaNs())
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateNaNs()): found %v, expected no change", f1, f2, delta)
+	}
+
+	delta, err = Compute(Foo{F: math.NaN()}, Foo{F: 1}, EquateNaNs())
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Error("Compute(..., EquateNaNs()): found no change, expected a MOD since only one side is NaN")
+	}
+}
+
+func TestComputeEquateInfs(t *testing.T) {
+	type Foo struct {
+		F float64
+	}
+	f1 := Foo{F: math.Inf(1)}
+	f2 := Foo{F: math.Inf(1)}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Fatalf("Compute(%v, %v): found no change, expected a MOD since +Inf - +Inf is NaN by default", f1, f2)
+	}
+
+	delta, err = Compute(f1, f2, EquateInfs())
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateInfs()): found %v, expected no change", f1, f2, delta)
+	}
+
+	delta, err = Compute(Foo{F: math.Inf(1)}, Foo{F: math.Inf(-1)}, EquateInfs())
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Error("Compute(..., EquateInfs()): found no change, expected a MOD since the infinities have opposite signs")
+	}
+}
+
+func TestComputeEquateFloatBits(t *testing.T) {
+	type Foo struct {
+		F float64
+	}
+	x := 1.0
+	y := math.Nextafter(x, 2)
+	z := math.Nextafter(y, 2)
+
+	delta, err := Compute(Foo{F: x}, Foo{F: y}, EquateFloatBits(1))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateFloatBits(1)): found %v, expected no change, 1 ULP apart", x, y, delta)
+	}
+
+	delta, err = Compute(Foo{F: x}, Foo{F: z}, EquateFloatBits(1))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateFloatBits(1)): found no change, expected a MOD, 2 ULPs apart", x, z)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeHeterogeneousMap(t *testing.T) {
+	type Foo struct {
+		M map[string]interface{}
+	}
+	f1 := Foo{M: map[string]interface{}{
+		"a": "hello",
+		"b": 42,
+		"c": "removed",
+	}}
+	f2 := Foo{M: map[string]interface{}{
+		"a": "world",
+		"b": 42,
+		"d": "added",
+	}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	changes, ok := delta["M"].(map[string]Change)
+	if !ok {
+		t.Fatalf("Compute(...): found %#v, expected map[string]Change under key 'M'", delta["M"])
+	}
+	if c, ok := changes["a"]; !ok || c.Type != ModType {
+		t.Errorf("changes[\"a\"]: found %#v, expected a MOD", changes["a"])
+	}
+	if c, ok := changes["c"]; !ok || c.Type != DelType {
+		t.Errorf("changes[\"c\"]: found %#v, expected a DEL", changes["c"])
+	}
+	if c, ok := changes["d"]; !ok || c.Type != AddType {
+		t.Errorf("changes[\"d\"]: found %#v, expected an ADD", changes["d"])
+	}
+	if _, ok := changes["b"]; ok {
+		t.Errorf("changes[\"b\"]: found a change, expected none since the value is identical")
+	}
+}
+
+func TestComputeNestedIntKeyedMap(t *testing.T) {
+	type Bar struct {
+		StringVal string
+	}
+	type Foo struct {
+		M map[int]Bar
+	}
+	f1 := Foo{M: map[int]Bar{1: {StringVal: "a"}, 2: {StringVal: "b"}}}
+	f2 := Foo{M: map[int]Bar{1: {StringVal: "a"}, 2: {StringVal: "c"}}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	changes, ok := delta["M"].(map[string]Change)
+	if !ok {
+		t.Fatalf("Compute(...): found %#v, expected map[string]Change under key 'M'", delta["M"])
+	}
+	c, ok := changes["2"]
+	if !ok || c.Type != ModType {
+		t.Fatalf("changes[\"2\"]: found %#v, expected a MOD", changes["2"])
+	}
+	nested, ok := c.Val.(Diff)
+	if !ok {
+		t.Fatalf("changes[\"2\"].Val: found %#v, expected a nested Diff", c.Val)
+	}
+	if _, ok := nested["StringVal"]; !ok {
+		t.Errorf("nested diff: found %#v, expected a change on 'StringVal'", nested)
+	}
+}
+
+func TestComputeInterfaceTypeChange(t *testing.T) {
+	type Foo struct {
+		V interface{}
+	}
+	f1 := Foo{V: "a string"}
+	f2 := Foo{V: 42}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	c, ok := delta["V"].(Change)
+	if !ok || c.Type != ModType {
+		t.Fatalf("Compute(...): found %#v, expected a MOD on 'V'", delta["V"])
+	}
+	if !reflect.DeepEqual(c.OldVal, "a string") || !reflect.DeepEqual(c.NewVal, 42) {
+		t.Errorf("changes[\"V\"]: found %#v, expected old='a string' new=42", c)
+	}
+}
+
+func TestComputeComplex(t *testing.T) {
+	type Foo struct {
+		C complex128
+	}
+	f1 := Foo{C: complex(1, 2)}
+	f2 := Foo{C: complex(1, 3)}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Fatal("Compute(...): found no change, expected a MOD on 'C'")
+	}
+
+	delta, err = Compute(Foo{C: complex(1, 2)}, Foo{C: complex(1, 2)})
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(...): found %v, expected no change for identical complex numbers", delta)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// SliceDiffMode selects the algorithm used by an Engine to compare slices
+// and arrays.
+type SliceDiffMode int
+
+// Possible values for a SliceDiffMode.
+const (
+	// SliceDiffIndex compares slice elements index by index. It is the
+	// Engine default and matches the historical behavior of this
+	// package: inserting a single element shifts every following index,
+	// so a single insertion can be reported as N modifications.
+	SliceDiffIndex SliceDiffMode = iota
+
+	// SliceDiffLCS aligns slice elements on their longest common
+	// subsequence using a Myers diff, so that insertions and deletions
+	// are reported as such instead of shifting the indices of unrelated
+	// elements.
+	SliceDiffLCS
+)
+
+// SliceRebindThreshold is the default minimum similarity ratio (see
+// Engine.SliceRebindThreshold) at or above which a deletion immediately
+// followed by an insertion is rebound into a single modification instead of being
+// reported as a DEL and an ADD.
+const SliceRebindThreshold = 0.5
+
+// effectiveSliceRebindThreshold returns the similarity ratio to use when
+// deciding whether to rebind a DEL/ADD pair into a MOD.
+func (e Engine) effectiveSliceRebindThreshold() float64 {
+	if e.SliceRebindThreshold > 0 {
+		return e.SliceRebindThreshold
+	}
+	return SliceRebindThreshold
+}
+
+// myersOp is a single step of a Myers edit script. OldX and NewY are only
+// meaningful for the side matching Type: a DelType only sets OldX, an
+// AddType only sets NewY.
+type myersOp struct {
+	Type ChangeType
+	OldX int
+	NewY int
+}
+
+// myersDiff computes the edit script turning a sequence of length n into a
+// sequence of length m, using eq(i, j) to decide whether element i of the
+// first sequence equals element j of the second. It implements Myers'
+// greedy O((N+M)D) algorithm.
+func myersDiff(n, m int, eq func(i, j int) bool) []myersOp {
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	v := make([]int, 2*max+1)
+	trace := make([][]int, 0, max+1)
+
+	var d int
+found:
+	for d = 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(x, y) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break found
+			}
+		}
+	}
+
+	ops := make([]myersOp, 0, d)
+	x, y := n, m
+	for depth := d; depth > 0; depth-- {
+		vv := trace[depth]
+		k := x - y
+
+		var prevK int
+		if k == -depth || (k != depth && vv[offset+k-1] < vv[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vv[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+		}
+
+		if x == prevX {
+			ops = append(ops, myersOp{Type: AddType, NewY: prevY})
+		} else {
+			ops = append(ops, myersOp{Type: DelType, OldX: prevX})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	// ops was built walking backward from the end of the sequences;
+	// reverse it to get a script that reads from the start.
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// similarity reports how alike x and y are, as a ratio in [0, 1], used to
+// decide whether a deletion and the insertion that immediately follows it
+// should be rebound into a single modification. Structures are scored by
+// the fraction of exported fields that are equal; every other kind scores
+// either 0 or 1.
+func similarity(x, y reflect.Value) float64 {
+	if x.Kind() != y.Kind() {
+		return 0
+	}
+	if x.Kind() != reflect.Struct {
+		if reflect.DeepEqual(x.Interface(), y.Interface()) {
+			return 1
+		}
+		return 0
+	}
+
+	total, same := 0, 0
+	for i := 0; i < x.NumField(); i++ {
+		name := x.Type().Field(i).Name
+		if !isExported(name) {
+			continue
+		}
+		total++
+		if reflect.DeepEqual(x.Field(i).Interface(), y.FieldByName(name).Interface()) {
+			same++
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(same) / float64(total)
+}
+
+// compareSlicesLCS compares fx and fy by aligning their elements on their
+// longest common subsequence (see SliceDiffLCS), instead of comparing them
+// index by index.
+//
+// Changes are keyed by "oldIndex:newIndex", with the side that does not
+// apply left empty, so that consumers can reconstruct where an element
+// came from and where it went.
+func (e Engine) compareSlicesLCS(fx, fy reflect.Value, path Path) interface{} {
+	xLen, yLen := fx.Len(), fy.Len()
+	ops := myersDiff(xLen, yLen, func(i, j int) bool {
+		return e.compareValues(fx.Index(i), fy.Index(j), path) == nil
+	})
+
+	threshold := e.effectiveSliceRebindThreshold()
+	changes := make(SliceChanges)
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		switch op.Type {
+		case DelType:
+			if i+1 < len(ops) && ops[i+1].Type == AddType {
+				next := ops[i+1]
+				if similarity(fx.Index(op.OldX), fy.Index(next.NewY)) >= threshold {
+					key := strconv.Itoa(op.OldX) + ":" + strconv.Itoa(next.NewY)
+					childPath := path.child(PathStep{Index: op.OldX})
+					scoped := e.scopedAt(childPath)
+					scoped.notifyPush(childPath.Last())
+					d := scoped.compareValues(fx.Index(op.OldX), fy.Index(next.NewY), childPath)
+					scoped.notifyResult(d)
+					scoped.notifyPop()
+					changes[key] = Change{Val: d, Type: ModType}
+					i++
+					continue
+				}
+			}
+			key := strconv.Itoa(op.OldX) + ":"
+			c := Change{OldVal: fx.Index(op.OldX).Interface(), Type: DelType}
+			changes[key] = c
+			e.reportChange(path.child(PathStep{Index: op.OldX}), c)
+		case AddType:
+			key := ":" + strconv.Itoa(op.NewY)
+			c := Change{NewVal: fy.Index(op.NewY).Interface(), Type: AddType}
+			changes[key] = c
+			e.reportChange(path.child(PathStep{Index: op.NewY}), c)
+		}
+	}
+
+	if len(changes) > 0 {
+		return changes
+	}
+	return nil
+}
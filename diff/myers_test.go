@@ -0,0 +1,102 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSlicesLCSInsertHead(t *testing.T) {
+	fx := []string{"A", "B", "C"}
+	fy := []string{"X", "A", "B", "C"}
+	e := Engine{SliceDiffMode: SliceDiffLCS}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	expected := SliceChanges{
+		":0": {NewVal: "X", Type: AddType},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected %#v", fx, fy, delta, expected)
+	}
+}
+
+func TestCompareSlicesLCSInsertMiddle(t *testing.T) {
+	fx := []string{"A", "B", "C"}
+	fy := []string{"A", "X", "B", "C"}
+	e := Engine{SliceDiffMode: SliceDiffLCS}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	expected := SliceChanges{
+		":1": {NewVal: "X", Type: AddType},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected %#v", fx, fy, delta, expected)
+	}
+}
+
+func TestCompareSlicesLCSInsertTail(t *testing.T) {
+	fx := []string{"A", "B", "C"}
+	fy := []string{"A", "B", "C", "X"}
+	e := Engine{SliceDiffMode: SliceDiffLCS}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	expected := SliceChanges{
+		":3": {NewVal: "X", Type: AddType},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected %#v", fx, fy, delta, expected)
+	}
+}
+
+func TestCompareSlicesLCSDelete(t *testing.T) {
+	fx := []string{"A", "B", "C"}
+	fy := []string{"A", "C"}
+	e := Engine{SliceDiffMode: SliceDiffLCS}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	expected := SliceChanges{
+		"1:": {OldVal: "B", Type: DelType},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected %#v", fx, fy, delta, expected)
+	}
+}
+
+func TestCompareSlicesLCSRebindModification(t *testing.T) {
+	type Bar struct {
+		Name  string
+		Value int
+	}
+	fx := []Bar{{Name: "a", Value: 1}, {Name: "b", Value: 2}}
+	fy := []Bar{{Name: "a", Value: 1}, {Name: "b", Value: 42}}
+	e := Engine{SliceDiffMode: SliceDiffLCS}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	changes, ok := delta.(SliceChanges)
+	if !ok {
+		t.Fatalf("compareSlices(%v, %v): found %#v, expected a SliceChanges", fx, fy, delta)
+	}
+	c, ok := changes["1:1"]
+	if !ok || c.Type != ModType {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected a MOD at key '1:1'", fx, fy, changes)
+	}
+}
+
+func TestCompareSlicesLCSNoRebindBelowThreshold(t *testing.T) {
+	type Bar struct {
+		Name  string
+		Value int
+	}
+	fx := []Bar{{Name: "a", Value: 1}, {Name: "b", Value: 2}}
+	fy := []Bar{{Name: "a", Value: 1}, {Name: "z", Value: 99}}
+	e := Engine{SliceDiffMode: SliceDiffLCS, SliceRebindThreshold: 0.9}
+	delta := e.compareSlices(reflect.ValueOf(fx), reflect.ValueOf(fy), nil)
+	changes, ok := delta.(SliceChanges)
+	if !ok {
+		t.Fatalf("compareSlices(%v, %v): found %#v, expected a SliceChanges", fx, fy, delta)
+	}
+	if _, ok := changes["1:"]; !ok {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected a DEL at key '1:'", fx, fy, changes)
+	}
+	if _, ok := changes[":1"]; !ok {
+		t.Errorf("compareSlices(%v, %v): found %#v, expected an ADD at key ':1'", fx, fy, changes)
+	}
+}
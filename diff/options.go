@@ -0,0 +1,219 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "reflect"
+
+// An Option configures how an Engine computes a Diff. Options are applied
+// in the order they are passed to Compute or Engine.Compute.
+type Option interface {
+	apply(*Engine)
+}
+
+// optionFunc adapts a plain function to the Option interface.
+type optionFunc func(*Engine)
+
+func (f optionFunc) apply(e *Engine) { f(e) }
+
+// ignoreFieldsRule ignores named fields of a specific struct type.
+type ignoreFieldsRule struct {
+	typ   reflect.Type
+	names []string
+}
+
+// IgnoreFields returns an Option that ignores the named fields of typ,
+// instead of every field sharing that name across all types the way
+// Engine.ExcludeFieldList does.
+func IgnoreFields(typ interface{}, names ...string) Option {
+	t := reflect.TypeOf(typ)
+	return optionFunc(func(e *Engine) {
+		e.ignoreFields = append(e.ignoreFields, ignoreFieldsRule{typ: t, names: names})
+	})
+}
+
+// IgnoreTypes returns an Option that skips any value whose type matches one
+// of types, wherever it is found in the compared structures.
+func IgnoreTypes(types ...interface{}) Option {
+	return optionFunc(func(e *Engine) {
+		for _, typ := range types {
+			e.ignoreTypes = append(e.ignoreTypes, reflect.TypeOf(typ))
+		}
+	})
+}
+
+// IgnoreUnexported returns an Option that skips the unexported fields of
+// the given struct types instead of comparing them as an opaque value (the
+// default behavior for structures made entirely of unexported fields, such
+// as time.Time).
+func IgnoreUnexported(types ...interface{}) Option {
+	return optionFunc(func(e *Engine) {
+		for _, typ := range types {
+			e.ignoreUnexportedTypes = append(e.ignoreUnexportedTypes, reflect.TypeOf(typ))
+		}
+	})
+}
+
+// pathFilter applies opt only to the values whose Path satisfies pred.
+type pathFilter struct {
+	pred func(Path) bool
+	opt  Option
+}
+
+// FilterPath returns an Option that only applies opt to the values whose
+// Path satisfies pred, leaving the rest of the comparison untouched. It
+// lets a caller scope an otherwise global option, e.g. an EquateApprox
+// tolerance that should only apply under a specific field.
+func FilterPath(pred func(Path) bool, opt Option) Option {
+	return optionFunc(func(e *Engine) {
+		e.filters = append(e.filters, pathFilter{pred: pred, opt: opt})
+	})
+}
+
+// scopedAt returns a copy of e with every filter matching path applied, for
+// use while comparing the value found at that path.
+func (e Engine) scopedAt(path Path) Engine {
+	if len(e.filters) == 0 {
+		return e
+	}
+	scoped := e
+	for _, f := range e.filters {
+		if f.pred(path) {
+			f.opt.apply(&scoped)
+		}
+	}
+	return scoped
+}
+
+// comparerRule holds a user-supplied equality function for a specific type.
+type comparerRule struct {
+	typ reflect.Type
+	fn  reflect.Value // func(T, T) bool
+}
+
+// Comparer returns an Option that uses fn, of type func(T, T) bool, to
+// decide whether two values of type T are equal instead of recursing into
+// them. It is meant for opaque types for which structural comparison does
+// not make sense.
+func Comparer(fn interface{}) Option {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	return optionFunc(func(e *Engine) {
+		e.comparers = append(e.comparers, comparerRule{typ: t.In(0), fn: v})
+	})
+}
+
+func (e Engine) findComparer(typ reflect.Type) (reflect.Value, bool) {
+	for _, c := range e.comparers {
+		if c.typ == typ {
+			return c.fn, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// transformerRule holds a user-supplied conversion used before comparison.
+type transformerRule struct {
+	name string
+	typ  reflect.Type // T
+	fn   reflect.Value
+}
+
+// Transformer returns an Option that rewrites every value of type T,
+// reflect.TypeOf(fn).In(0), into fn(value) before comparing it, using fn of
+// type func(T) U. It is useful to compare opaque types through a derived,
+// comparable representation (e.g. a *regexp.Regexp through its pattern
+// string).
+func Transformer(name string, fn interface{}) Option {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	return optionFunc(func(e *Engine) {
+		e.transformers = append(e.transformers, transformerRule{name: name, typ: t.In(0), fn: v})
+	})
+}
+
+func (e Engine) findTransformer(typ reflect.Type) (transformerRule, bool) {
+	for _, t := range e.transformers {
+		if t.typ == typ {
+			return t, true
+		}
+	}
+	return transformerRule{}, false
+}
+
+// EquateApprox returns an Option that considers two float32 or float64
+// values equal when |x-y| <= max(margin, fraction*max(|x|,|y|)), combining
+// an absolute and a relative tolerance. It replaces the package-level
+// Tolerance constant for the Engine it is applied to.
+func EquateApprox(fraction, margin float64) Option {
+	return optionFunc(func(e *Engine) {
+		e.floatEquate = approxFloatEquate{fraction: fraction, margin: margin}
+	})
+}
+
+// EquateNaNs returns an Option that considers two float32 or float64 values
+// equal when both are NaN, instead of the default IEEE 754 behavior in
+// which NaN never equals anything, including itself.
+func EquateNaNs() Option {
+	return optionFunc(func(e *Engine) {
+		e.equateNaNs = true
+	})
+}
+
+// EquateInfs returns an Option that considers two float32 or float64 values
+// equal when both are an infinity of the same sign. Without it, an infinity
+// minus an infinity of the same sign yields NaN, so a pair of equal
+// infinities is reported as a change by the default tolerance-based
+// comparison.
+func EquateInfs() Option {
+	return optionFunc(func(e *Engine) {
+		e.equateInfs = true
+	})
+}
+
+// EquateFloatBits returns an Option that considers two float32 or float64
+// values equal when their IEEE 754 bit patterns are within ulps units in
+// the last place of one another, instead of using Tolerance or an
+// EquateApprox margin. EquateFloatBits and EquateApprox set the same
+// comparison: whichever of them is applied last to an Engine takes effect.
+func EquateFloatBits(ulps uint32) Option {
+	return optionFunc(func(e *Engine) {
+		e.floatEquate = ulpsFloatEquate{ulps: ulps}
+	})
+}
+
+func (e Engine) isFieldIgnored(parentType reflect.Type, name string) bool {
+	if e.IsIgnored(name) {
+		return true
+	}
+	for _, r := range e.ignoreFields {
+		if r.typ != parentType {
+			continue
+		}
+		for _, n := range r.names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e Engine) isTypeIgnored(typ reflect.Type) bool {
+	for _, t := range e.ignoreTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Engine) isUnexportedIgnored(typ reflect.Type) bool {
+	for _, t := range e.ignoreUnexportedTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
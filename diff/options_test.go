@@ -0,0 +1,163 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeIgnoreFields(t *testing.T) {
+	type Foo struct {
+		A string
+		B string
+	}
+	f1 := Foo{A: "a1", B: "b1"}
+	f2 := Foo{A: "a2", B: "b1"}
+
+	delta, err := Compute(f1, f2, IgnoreFields(Foo{}, "A"))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, IgnoreFields(Foo{}, \"A\")): found %v, expected no change", f1, f2, delta)
+	}
+}
+
+func TestComputeIgnoreTypes(t *testing.T) {
+	type Foo struct {
+		A string
+		B int
+	}
+	f1 := Foo{A: "a1", B: 1}
+	f2 := Foo{A: "a2", B: 2}
+
+	delta, err := Compute(f1, f2, IgnoreTypes(""))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if _, ok := delta["A"]; ok {
+		t.Errorf("Compute(%v, %v, IgnoreTypes(\"\")): found field 'A' in %v, expected it to be ignored", f1, f2, delta)
+	}
+	if _, ok := delta["B"]; !ok {
+		t.Errorf("Compute(%v, %v, IgnoreTypes(\"\")): found %v, expected field 'B' to be reported", f1, f2, delta)
+	}
+}
+
+func TestComputeIgnoreUnexported(t *testing.T) {
+	type Foo struct {
+		When time.Time
+	}
+	f1 := Foo{When: time.Date(2016, time.June, 22, 10, 0, 0, 0, time.UTC)}
+	f2 := Foo{When: time.Date(2017, time.June, 22, 10, 0, 0, 0, time.UTC)}
+
+	delta, err := Compute(f1, f2, IgnoreUnexported(time.Time{}))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, IgnoreUnexported(time.Time{})): found %v, expected no change", f1, f2, delta)
+	}
+}
+
+func TestComputeFilterPath(t *testing.T) {
+	type Bar struct {
+		FloatVal float64
+	}
+	type Foo struct {
+		A Bar
+		B Bar
+	}
+	f1 := Foo{A: Bar{FloatVal: 1.0}, B: Bar{FloatVal: 1.0}}
+	f2 := Foo{A: Bar{FloatVal: 1.0001}, B: Bar{FloatVal: 1.0001}}
+
+	opt := FilterPath(func(p Path) bool {
+		return len(p) > 0 && p[0].Field == "A"
+	}, EquateApprox(0.01, 0))
+
+	delta, err := Compute(f1, f2, opt)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if _, ok := delta["A"]; ok {
+		t.Errorf("Compute(...): found field 'A' changed in %v, expected FilterPath to equate it", delta)
+	}
+	if _, ok := delta["B"]; !ok {
+		t.Errorf("Compute(...): found %v, expected field 'B' to still be reported as changed", delta)
+	}
+}
+
+func TestComputeComparer(t *testing.T) {
+	type Foo struct {
+		A string
+	}
+	f1 := Foo{A: "hello"}
+	f2 := Foo{A: "HELLO"}
+
+	caseInsensitive := Comparer(func(a, b string) bool {
+		return strings.EqualFold(a, b)
+	})
+
+	delta, err := Compute(f1, f2, caseInsensitive)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, Comparer(...)): found %v, expected no change", f1, f2, delta)
+	}
+}
+
+func TestComputeTransformer(t *testing.T) {
+	type Foo struct {
+		A string
+	}
+	f1 := Foo{A: "Hello"}
+	f2 := Foo{A: "HELLO"}
+
+	upper := Transformer("toUpper", func(s string) string {
+		result := make([]byte, len(s))
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			if c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			result[i] = c
+		}
+		return string(result)
+	})
+
+	delta, err := Compute(f1, f2, upper)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, Transformer(...)): found %v, expected no change", f1, f2, delta)
+	}
+}
+
+func TestComputeEquateApprox(t *testing.T) {
+	type Foo struct {
+		A float64
+	}
+	f1 := Foo{A: 1.0}
+	f2 := Foo{A: 1.005}
+
+	delta, err := Compute(f1, f2, EquateApprox(0.01, 0))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateApprox(0.01, 0)): found %v, expected no change", f1, f2, delta)
+	}
+
+	delta, err = Compute(f1, f2, EquateApprox(0.0001, 0))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	if !delta.HasChange() {
+		t.Errorf("Compute(%v, %v, EquateApprox(0.0001, 0)): found no change, expected a diff", f1, f2)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extractJSONName extracts the field name out of a `json:"..."` struct tag
+// value, dropping any trailing options (e.g. ",omitempty"). It returns an
+// empty string for the "-" tag, which means "no JSON name".
+func extractJSONName(tag string) string {
+	if tag == "-" {
+		return ""
+	}
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		return tag[:idx]
+	}
+	return tag
+}
+
+// fieldKey returns the name a Diff uses to key field, honoring its `json`
+// struct tag when present so that Diff.JSONPatch produces JSON Pointer
+// paths matching the struct's wire format. It falls back to the Go field
+// name when there is no tag, or when the tag carries no name (e.g. "-" or
+// ",omitempty").
+func fieldKey(field reflect.StructField) string {
+	if name := extractJSONName(field.Tag.Get("json")); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// Op is the kind of mutation a JSON Patch Operation applies, as defined by
+// RFC 6902.
+type Op string
+
+// Possible values for an Op.
+const (
+	OpAdd     Op = "add"
+	OpRemove  Op = "remove"
+	OpReplace Op = "replace"
+)
+
+// An Operation is a single step of a JSON Patch (RFC 6902): a mutation
+// applied at a JSON Pointer (RFC 6901) path.
+type Operation struct {
+	Op    Op          `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	From  string      `json:"from,omitempty"`
+}
+
+// Operations flattens the Diff into a path-addressable slice of Operation,
+// ordered for deterministic output and for safe sequential application: see
+// pathLess.
+func (d Diff) Operations() []Operation {
+	var ops []Operation
+	for key, val := range d {
+		walkDiff("/"+pointerToken(key), val, &ops)
+	}
+	sort.Slice(ops, func(i, j int) bool { return pathLess(ops[i], ops[j]) })
+	return ops
+}
+
+// pathLess orders a and b by comparing their Path reference token by
+// reference token, numerically rather than lexicographically when both
+// tokens are array indices (so that, e.g., "/Tags/2" sorts before
+// "/Tags/10"). Among a pair of OpRemove operations that diverge on an
+// index, the higher index sorts first, so that Apply removes elements from
+// the tail of a slice first and never shifts the index of a pending
+// removal still to come.
+func pathLess(a, b Operation) bool {
+	at, _ := splitPointer(a.Path)
+	bt, _ := splitPointer(b.Path)
+
+	n := len(at)
+	if len(bt) < n {
+		n = len(bt)
+	}
+	for i := 0; i < n; i++ {
+		if at[i] == bt[i] {
+			continue
+		}
+		ai, aerr := strconv.Atoi(at[i])
+		bi, berr := strconv.Atoi(bt[i])
+		if aerr != nil || berr != nil {
+			return at[i] < bt[i]
+		}
+		if a.Op == OpRemove && b.Op == OpRemove {
+			return ai > bi
+		}
+		return ai < bi
+	}
+	return len(at) < len(bt)
+}
+
+// JSONPatch serializes the Diff as a JSON Patch document conforming to
+// RFC 6902.
+func (d Diff) JSONPatch() []byte {
+	bs, err := json.Marshal(d.Operations())
+	if err != nil {
+		return []byte{}
+	}
+	return bs
+}
+
+func walkDiff(path string, val interface{}, ops *[]Operation) {
+	switch v := val.(type) {
+	case Diff:
+		for key, child := range v {
+			walkDiff(path+"/"+pointerToken(key), child, ops)
+		}
+	case SliceChanges:
+		for key, change := range v {
+			childPath := path + "/" + sliceKeyToken(key)
+			walkChange(childPath, change, ops)
+		}
+	case map[string]Change:
+		for key, change := range v {
+			childPath := path + "/" + pointerToken(key)
+			walkChange(childPath, change, ops)
+		}
+	case Change:
+		walkChange(path, v, ops)
+	}
+}
+
+func walkChange(path string, c Change, ops *[]Operation) {
+	switch v := c.Val.(type) {
+	case Diff, SliceChanges, map[string]Change:
+		walkDiff(path, v, ops)
+		return
+	case Change:
+		walkChange(path, v, ops)
+		return
+	}
+
+	switch c.Type {
+	case AddType:
+		*ops = append(*ops, Operation{Op: OpAdd, Path: path, Value: c.NewVal})
+	case DelType:
+		*ops = append(*ops, Operation{Op: OpRemove, Path: path})
+	case ModType:
+		*ops = append(*ops, Operation{Op: OpReplace, Path: path, Value: c.NewVal})
+	}
+}
+
+// pointerToken escapes s as a single JSON Pointer (RFC 6901) reference
+// token: "~" becomes "~0" and "/" becomes "~1".
+func pointerToken(s string) string {
+	s = strings.Replace(s, "~", "~0", -1)
+	s = strings.Replace(s, "/", "~1", -1)
+	return s
+}
+
+// sliceKeyToken turns a compareSlices map key into the single JSON Pointer
+// index it refers to. Index-mode keys ("0", "1", ...) are used as is. LCS
+// keys ("oldIndex:newIndex", see SliceDiffLCS) collapse to the new index
+// for an ADD or MOD, and to the old index for a DEL.
+func sliceKeyToken(key string) string {
+	idx := strings.IndexByte(key, ':')
+	if idx < 0 {
+		return pointerToken(key)
+	}
+	oldIdx, newIdx := key[:idx], key[idx+1:]
+	if newIdx != "" {
+		return newIdx
+	}
+	return oldIdx
+}
+
+// Apply applies a JSON Patch (RFC 6902), as produced by Diff.JSONPatch, to
+// a copy of orig and returns that copy. orig must be a struct, and the
+// patch's paths must address its exported fields and, transitively, the
+// elements of its slice and array fields.
+func Apply(orig interface{}, patch []byte) (interface{}, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(orig)
+	root := reflect.New(v.Type())
+	root.Elem().Set(v)
+
+	for _, op := range ops {
+		tokens, err := splitPointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyOperation(root.Elem(), tokens, op); err != nil {
+			return nil, err
+		}
+	}
+	return root.Elem().Interface(), nil
+}
+
+// splitPointer splits a JSON Pointer into its unescaped reference tokens.
+func splitPointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path[0] != '/' {
+		return nil, fmt.Errorf("diff: invalid JSON pointer %q", path)
+	}
+	raw := strings.Split(path[1:], "/")
+	tokens := make([]string, len(raw))
+	for i, t := range raw {
+		t = strings.Replace(t, "~1", "/", -1)
+		t = strings.Replace(t, "~0", "~", -1)
+		tokens[i] = t
+	}
+	return tokens, nil
+}
+
+// navigate walks tokens from v, dereferencing pointers along the way, and
+// returns the value the last token is a member of.
+func navigate(v reflect.Value, tokens []string) (reflect.Value, error) {
+	for _, tok := range tokens {
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			f, err := fieldByJSONName(v, tok)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v = f
+		case reflect.Slice, reflect.Array:
+			idx, err := strconv.Atoi(tok)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("diff: invalid array index %q", tok)
+			}
+			if idx < 0 || idx >= v.Len() {
+				return reflect.Value{}, fmt.Errorf("diff: array index %d out of range", idx)
+			}
+			v = v.Index(idx)
+		default:
+			return reflect.Value{}, fmt.Errorf("diff: cannot descend into a %s", v.Kind())
+		}
+	}
+	return v, nil
+}
+
+func applyOperation(root reflect.Value, tokens []string, op Operation) error {
+	if len(tokens) == 0 {
+		return errors.New("diff: empty JSON pointer")
+	}
+
+	target, err := navigate(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	for target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	last := tokens[len(tokens)-1]
+
+	switch target.Kind() {
+	case reflect.Struct:
+		field, err := fieldByJSONName(target, last)
+		if err != nil {
+			return err
+		}
+		switch op.Op {
+		case OpRemove:
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		case OpAdd, OpReplace:
+			return decodeValue(op.Value, field)
+		}
+		return fmt.Errorf("diff: unsupported op %q", op.Op)
+
+	case reflect.Slice:
+		idx, err := strconv.Atoi(last)
+		if err != nil && last != "-" {
+			return fmt.Errorf("diff: invalid array index %q", last)
+		}
+		switch op.Op {
+		case OpRemove:
+			target.Set(reflect.AppendSlice(target.Slice(0, idx), target.Slice(idx+1, target.Len())))
+			return nil
+		case OpAdd:
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeValue(op.Value, elem); err != nil {
+				return err
+			}
+			// A patch may legitimately point past the current end of the
+			// slice: an earlier OpAdd in the same patch, targeting a
+			// lower index of the same slice, grows it after this index
+			// was computed from the Diff's original length.
+			if last == "-" || idx >= target.Len() {
+				idx = target.Len()
+			}
+			grown := reflect.Append(target, elem)
+			reflect.Copy(grown.Slice(idx+1, grown.Len()), grown.Slice(idx, grown.Len()-1))
+			grown.Index(idx).Set(elem)
+			target.Set(grown)
+			return nil
+		case OpReplace:
+			return decodeValue(op.Value, target.Index(idx))
+		}
+		return fmt.Errorf("diff: unsupported op %q", op.Op)
+
+	case reflect.Array:
+		// An array's length is part of its type, so only its elements can
+		// be replaced.
+		idx, err := strconv.Atoi(last)
+		if err != nil || idx < 0 || idx >= target.Len() {
+			return fmt.Errorf("diff: invalid array index %q", last)
+		}
+		if op.Op != OpReplace {
+			return fmt.Errorf("diff: unsupported op %q on an array", op.Op)
+		}
+		return decodeValue(op.Value, target.Index(idx))
+	}
+
+	return fmt.Errorf("diff: cannot apply patch to a %s", target.Kind())
+}
+
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if fieldKey(field) == name {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("diff: unknown field %q", name)
+}
+
+// decodeValue converts value, as produced by decoding a JSON Patch document
+// into an Operation's interface{} Value, into dst, re-marshaling it through
+// encoding/json so that dst's concrete type drives the conversion.
+func decodeValue(value interface{}, dst reflect.Value) error {
+	if !dst.CanSet() {
+		return errors.New("diff: destination field cannot be set")
+	}
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(dst.Type())
+	if err := json.Unmarshal(bs, ptr.Interface()); err != nil {
+		return err
+	}
+	dst.Set(ptr.Elem())
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffOperations(t *testing.T) {
+	type Bar struct {
+		Name string `json:"name"`
+	}
+	type Foo struct {
+		IntVal int   `json:"int_val"`
+		Bar    Bar   `json:"bar"`
+		Tags   []int `json:"tags"`
+	}
+	f1 := Foo{IntVal: 1, Bar: Bar{Name: "a"}, Tags: []int{1, 2}}
+	f2 := Foo{IntVal: 2, Bar: Bar{Name: "b"}, Tags: []int{1, 2, 3}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	ops := delta.Operations()
+	want := map[string]Operation{
+		"/int_val":  {Op: OpReplace, Path: "/int_val", Value: int64(2)},
+		"/bar/name": {Op: OpReplace, Path: "/bar/name", Value: "b"},
+		"/tags/2":   {Op: OpAdd, Path: "/tags/2", Value: 3},
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("Operations(): found %d operations %#v, expected %d", len(ops), ops, len(want))
+	}
+	for _, op := range ops {
+		expected, ok := want[op.Path]
+		if !ok {
+			t.Errorf("Operations(): unexpected path %q", op.Path)
+			continue
+		}
+		if op.Op != expected.Op {
+			t.Errorf("Operations()[%q].Op: found %q, expected %q", op.Path, op.Op, expected.Op)
+		}
+	}
+}
+
+func TestDiffJSONPatchEscaping(t *testing.T) {
+	type Foo struct {
+		A string `json:"a/b~c"`
+	}
+	f1 := Foo{A: "x"}
+	f2 := Foo{A: "y"}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+	ops := delta.Operations()
+	if len(ops) != 1 {
+		t.Fatalf("Operations(): found %d operations, expected 1", len(ops))
+	}
+	if expected := "/a~1b~0c"; ops[0].Path != expected {
+		t.Errorf("Operations()[0].Path: found %q, expected %q", ops[0].Path, expected)
+	}
+}
+
+func TestApplyRoundTrip(t *testing.T) {
+	type Bar struct {
+		Name string
+	}
+	type Foo struct {
+		IntVal int
+		Bar    Bar
+		Tags   []int
+	}
+	f1 := Foo{IntVal: 1, Bar: Bar{Name: "a"}, Tags: []int{1, 2}}
+	f2 := Foo{IntVal: 2, Bar: Bar{Name: "b"}, Tags: []int{1, 2, 3}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	patched, err := Apply(f1, delta.JSONPatch())
+	if err != nil {
+		t.Fatal("Failed to apply patch: ", err)
+	}
+	if !reflect.DeepEqual(patched, f2) {
+		t.Errorf("Apply(f1, patch): found %#v, expected %#v", patched, f2)
+	}
+}
+
+func TestApplyRemove(t *testing.T) {
+	type Foo struct {
+		Tags []int
+	}
+	f1 := Foo{Tags: []int{1, 2, 3}}
+	f2 := Foo{Tags: []int{1, 3}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	patched, err := Apply(f1, delta.JSONPatch())
+	if err != nil {
+		t.Fatal("Failed to apply patch: ", err)
+	}
+	if !reflect.DeepEqual(patched, f2) {
+		t.Errorf("Apply(f1, patch): found %#v, expected %#v", patched, f2)
+	}
+}
+
+// TestApplyRoundTripTwoDigitIndices guards against Operations sorting paths
+// lexicographically, which puts "/Tags/10" before "/Tags/2" and makes Apply
+// insert into a still-short slice out of order.
+func TestApplyRoundTripTwoDigitIndices(t *testing.T) {
+	type Foo struct {
+		Tags []int
+	}
+	f1 := Foo{Tags: []int{0, 1}}
+	f2 := Foo{Tags: make([]int, 13)}
+	copy(f2.Tags, f1.Tags)
+	for i := 2; i < len(f2.Tags); i++ {
+		f2.Tags[i] = i
+	}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	patched, err := Apply(f1, delta.JSONPatch())
+	if err != nil {
+		t.Fatal("Failed to apply patch: ", err)
+	}
+	if !reflect.DeepEqual(patched, f2) {
+		t.Errorf("Apply(f1, patch): found %#v, expected %#v", patched, f2)
+	}
+}
+
+// TestDiffOperationsMapKeyWithColon guards against map[string]Change keys
+// being run through sliceKeyToken, which splits on ':' and would garble a
+// map key that itself contains one.
+func TestDiffOperationsMapKeyWithColon(t *testing.T) {
+	type Foo struct {
+		M map[string]int
+	}
+	f1 := Foo{M: map[string]int{"3:4": 1}}
+	f2 := Foo{M: map[string]int{"3:4": 2}}
+
+	delta, err := Compute(f1, f2)
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	ops := delta.Operations()
+	if len(ops) != 1 {
+		t.Fatalf("Operations(): found %d operations %#v, expected 1", len(ops), ops)
+	}
+	if expected := "/M/3:4"; ops[0].Path != expected {
+		t.Errorf("Operations()[0].Path: found %q, expected %q", ops[0].Path, expected)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A PathStep describes a single step while walking from the root of a
+// comparison down to the value being compared: a struct field, a slice or
+// array index, or a map key. Exactly one of Field, Index or Key applies to
+// a given step.
+type PathStep struct {
+	Field string      // set for a struct field step
+	Index int         // set for a slice/array step; 0 otherwise
+	Key   interface{} // set for a map step; nil otherwise
+}
+
+// String returns a human readable representation of the step, e.g. "Foo",
+// "3" or "bar".
+func (s PathStep) String() string {
+	switch {
+	case s.Field != "":
+		return s.Field
+	case s.Key != nil:
+		return fmt.Sprint(s.Key)
+	default:
+		return strconv.Itoa(s.Index)
+	}
+}
+
+// Path is the sequence of PathStep from the root of a comparison down to a
+// given value.
+type Path []PathStep
+
+// String joins the steps of the path with dots, e.g. "Foo.Bar.3".
+func (p Path) String() string {
+	parts := make([]string, len(p))
+	for i, s := range p {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, ".")
+}
+
+// Last returns the last step of the path, or the zero PathStep if the path
+// is empty (the root value).
+func (p Path) Last() PathStep {
+	if len(p) == 0 {
+		return PathStep{}
+	}
+	return p[len(p)-1]
+}
+
+// child returns a copy of p with step appended, so that callers can safely
+// keep using p for sibling values.
+func (p Path) child(step PathStep) Path {
+	child := make(Path, len(p)+1)
+	copy(child, p)
+	child[len(p)] = step
+	return child
+}
@@ -0,0 +1,188 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Result describes the outcome of comparing the two values found at the
+// Reporter's current path: whether they were equal and, if not, the Change
+// describing how they differ.
+type Result struct {
+	Equal  bool
+	Change Change
+}
+
+// A Reporter observes a comparison as Engine.Compute walks from the root of
+// the two structures down to each leaf value. PushStep is called before
+// descending into a value, Report once a leaf comparison has been made,
+// and PopStep after leaving it; calls are nested the same way the walk is.
+type Reporter interface {
+	PushStep(PathStep)
+	Report(Result)
+	PopStep()
+}
+
+// Reporters returns an Option that notifies rs, in order, of every step and
+// result produced while Compute walks the compared structures.
+func Reporters(rs ...Reporter) Option {
+	return optionFunc(func(e *Engine) {
+		e.reporters = append(e.reporters, rs...)
+	})
+}
+
+func (e Engine) notifyPush(step PathStep) {
+	for _, r := range e.reporters {
+		r.PushStep(step)
+	}
+}
+
+func (e Engine) notifyPop() {
+	for _, r := range e.reporters {
+		r.PopStep()
+	}
+}
+
+// notifyResult reports d, the result of comparing the values at the
+// current step, to every configured Reporter. Containers (Diff,
+// SliceChanges and map[string]Change) are not reported themselves: their
+// elements are reported individually as the walk descends into them.
+func (e Engine) notifyResult(d interface{}) {
+	if len(e.reporters) == 0 {
+		return
+	}
+	switch d.(type) {
+	case Diff, SliceChanges, map[string]Change:
+		return
+	}
+
+	res := Result{Equal: d == nil}
+	if c, ok := d.(Change); ok {
+		res.Change = c
+	}
+	for _, r := range e.reporters {
+		r.Report(res)
+	}
+}
+
+// containerReporter is an internal extension of Reporter, implemented only
+// by diffBuilder. The public Reporter contract only models leaf outcomes
+// through Result, which cannot carry a struct field's raw comparison
+// result (nil, a Change, or a nested Diff/SliceChanges/map[string]Change);
+// containerReporter lets diffBuilder observe that raw result instead, so
+// that Engine.Compute can source the Diff it returns from a Reporter
+// rather than from compareStructs's return value directly.
+type containerReporter interface {
+	Reporter
+	observeField(key string, raw interface{})
+}
+
+// notifyField notifies any containerReporter among the configured
+// reporters of the raw result of comparing a struct field, keyed the same
+// way compareStructs keys its own Diff.
+func (e Engine) notifyField(key string, raw interface{}) {
+	for _, r := range e.reporters {
+		if cr, ok := r.(containerReporter); ok {
+			cr.observeField(key, raw)
+		}
+	}
+}
+
+// TextReporter produces a unified, human-readable diff of the values found
+// to differ, one "-"/"+" pair (or a single line for an ADD/DEL) per
+// changed path, optionally colorized with ANSI escape codes.
+type TextReporter struct {
+	Color bool
+
+	path  Path
+	lines []string
+}
+
+// PushStep implements Reporter.
+func (r *TextReporter) PushStep(s PathStep) {
+	r.path = append(r.path, s)
+}
+
+// PopStep implements Reporter.
+func (r *TextReporter) PopStep() {
+	if len(r.path) > 0 {
+		r.path = r.path[:len(r.path)-1]
+	}
+}
+
+// Report implements Reporter.
+func (r *TextReporter) Report(res Result) {
+	if res.Equal {
+		return
+	}
+	path := r.path.String()
+	switch res.Change.Type {
+	case AddType:
+		r.lines = append(r.lines, r.colorize('+', fmt.Sprintf("+ %s: %v", path, res.Change.NewVal)))
+	case DelType:
+		r.lines = append(r.lines, r.colorize('-', fmt.Sprintf("- %s: %v", path, res.Change.OldVal)))
+	default:
+		r.lines = append(r.lines, r.colorize('-', fmt.Sprintf("- %s: %v", path, res.Change.OldVal)))
+		r.lines = append(r.lines, r.colorize('+', fmt.Sprintf("+ %s: %v", path, res.Change.NewVal)))
+	}
+}
+
+func (r *TextReporter) colorize(sign byte, line string) string {
+	if !r.Color {
+		return line
+	}
+	const (
+		red   = "\x1b[31m"
+		green = "\x1b[32m"
+		reset = "\x1b[0m"
+	)
+	if sign == '+' {
+		return green + line + reset
+	}
+	return red + line + reset
+}
+
+// String returns the accumulated unified diff.
+func (r *TextReporter) String() string {
+	return strings.Join(r.lines, "\n")
+}
+
+// SummaryReporter counts the changes found, by ChangeType.
+type SummaryReporter struct {
+	Counts map[ChangeType]int
+}
+
+// PushStep implements Reporter.
+func (r *SummaryReporter) PushStep(PathStep) {}
+
+// PopStep implements Reporter.
+func (r *SummaryReporter) PopStep() {}
+
+// Report implements Reporter.
+func (r *SummaryReporter) Report(res Result) {
+	if res.Equal {
+		return
+	}
+	if r.Counts == nil {
+		r.Counts = make(map[ChangeType]int)
+	}
+	r.Counts[res.Change.Type]++
+}
+
+// String returns a one-line human-readable summary, e.g. "2 MOD, 1 ADD".
+func (r *SummaryReporter) String() string {
+	var parts []string
+	for _, t := range []ChangeType{ModType, AddType, DelType} {
+		if n := r.Counts[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, t))
+		}
+	}
+	if len(parts) == 0 {
+		return "no change"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 e-Xpert Solutions SA. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextReporter(t *testing.T) {
+	type Foo struct {
+		A string
+		B int
+	}
+	f1 := Foo{A: "x", B: 1}
+	f2 := Foo{A: "y", B: 1}
+
+	var r TextReporter
+	if _, err := Compute(f1, f2, Reporters(&r)); err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	out := r.String()
+	if !strings.Contains(out, "- A: x") || !strings.Contains(out, "+ A: y") {
+		t.Errorf("TextReporter.String(): found %q, expected lines for field A", out)
+	}
+	if strings.Contains(out, "B:") {
+		t.Errorf("TextReporter.String(): found %q, expected no line for unchanged field B", out)
+	}
+}
+
+func TestComputeWithReportersStillReturnsDiff(t *testing.T) {
+	type Foo struct {
+		A string
+		B []int
+	}
+	f1 := Foo{A: "x", B: []int{1, 2}}
+	f2 := Foo{A: "y", B: []int{1, 2, 3}}
+
+	var text TextReporter
+	var summary SummaryReporter
+	delta, err := Compute(f1, f2, Reporters(&text, &summary))
+	if err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	if !delta.HasChange() {
+		t.Fatal("Compute(f1, f2, Reporters(...)): found no change, expected A and B to differ")
+	}
+	if delta["A"] != (Change{OldVal: "x", NewVal: "y", Type: ModType}) {
+		t.Errorf(`Compute(...)["A"]: found %#v, expected a MOD from "x" to "y"`, delta["A"])
+	}
+	want := SliceChanges{"2": Change{NewVal: 3, Type: AddType}}
+	if !reflect.DeepEqual(delta["B"], want) {
+		t.Errorf("Compute(...)[\"B\"]: found %#v, expected %#v", delta["B"], want)
+	}
+}
+
+func TestSummaryReporter(t *testing.T) {
+	type Foo struct {
+		IntList []int
+	}
+	f1 := Foo{IntList: []int{1, 2}}
+	f2 := Foo{IntList: []int{1, 3, 4}}
+
+	var r SummaryReporter
+	if _, err := Compute(f1, f2, Reporters(&r)); err != nil {
+		t.Fatal("Failed to compute diff: ", err)
+	}
+
+	if r.Counts[ModType] != 1 {
+		t.Errorf("SummaryReporter.Counts[ModType]: found %d, expected 1", r.Counts[ModType])
+	}
+	if r.Counts[AddType] != 1 {
+		t.Errorf("SummaryReporter.Counts[AddType]: found %d, expected 1", r.Counts[AddType])
+	}
+}